@@ -0,0 +1,204 @@
+// Package lifecycle replaces ad-hoc start/stop ordering in main with a
+// small dependency-aware manager. Services register once with Register,
+// the manager computes a topological start order from their declared
+// dependencies, and reverses it for shutdown. This is the same shape used
+// by flynn's shutdown package: a BeforeExit hook lets background
+// goroutines register their own cleanup without main knowing about them.
+package lifecycle
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/cjlapao/lxc-agent/internal/logging"
+)
+
+// log tags this package's logging with component "lifecycle", so
+// logging.subsystems.lifecycle=<level> can raise or lower its verbosity
+// independently of the rest of the agent.
+var log = logging.WithComponent("lifecycle")
+
+// StartFn brings a service up. It is given a context bounded by the
+// manager's start timeout.
+type StartFn func(ctx context.Context) error
+
+// StopFn tears a service down. It is given a context bounded by the
+// manager's stop timeout (in turn bounded by the Shutdown deadline).
+type StopFn func(ctx context.Context) error
+
+type service struct {
+	name  string
+	start StartFn
+	stop  StopFn
+	deps  []string
+}
+
+// Manager owns the registered services and the order they start/stop in.
+type Manager struct {
+	mu       sync.Mutex
+	services map[string]*service
+	order    []string // registration order, used to break ties deterministically
+
+	beforeExit []func()
+
+	startTimeout time.Duration
+	stopTimeout  time.Duration
+}
+
+// New builds a Manager with the given per-service start/stop timeout.
+func New(serviceTimeout time.Duration) *Manager {
+	return &Manager{
+		services:     map[string]*service{},
+		startTimeout: serviceTimeout,
+		stopTimeout:  serviceTimeout,
+	}
+}
+
+// Register adds a service identified by name. deps names services that
+// must finish starting before this one, and that this one must stop
+// before. start or stop may be nil if the service has no work to do in
+// that direction.
+func (m *Manager) Register(name string, start StartFn, stop StopFn, deps ...string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.services[name] = &service{name: name, start: start, stop: stop, deps: deps}
+	m.order = append(m.order, name)
+}
+
+// BeforeExit registers a cleanup hook that runs alongside service shutdown.
+// It is meant for background goroutines that have no dedicated Register
+// entry of their own.
+func (m *Manager) BeforeExit(fn func()) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.beforeExit = append(m.beforeExit, fn)
+}
+
+// startOrder topologically sorts registered services by their deps,
+// breaking ties by registration order so the result is deterministic.
+func (m *Manager) startOrder() ([]string, error) {
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+	state := make(map[string]int, len(m.order))
+	order := make([]string, 0, len(m.order))
+
+	var visit func(name string) error
+	visit = func(name string) error {
+		switch state[name] {
+		case done:
+			return nil
+		case visiting:
+			return fmt.Errorf("lifecycle: circular dependency detected at %q", name)
+		}
+
+		svc, ok := m.services[name]
+		if !ok {
+			return fmt.Errorf("lifecycle: unknown dependency %q", name)
+		}
+
+		state[name] = visiting
+		for _, dep := range svc.deps {
+			if err := visit(dep); err != nil {
+				return err
+			}
+		}
+		state[name] = done
+		order = append(order, name)
+		return nil
+	}
+
+	for _, name := range m.order {
+		if err := visit(name); err != nil {
+			return nil, err
+		}
+	}
+	return order, nil
+}
+
+// Start brings up every registered service in dependency order. It stops
+// at the first failure and returns without rolling back services already
+// started; the caller is expected to exit the process on error.
+func (m *Manager) Start(ctx context.Context) error {
+	order, err := m.startOrder()
+	if err != nil {
+		return err
+	}
+
+	for _, name := range order {
+		svc := m.services[name]
+		if svc.start == nil {
+			continue
+		}
+
+		log.Infof("Starting service %q...", name)
+		startCtx, cancel := context.WithTimeout(ctx, m.startTimeout)
+		err := svc.start(startCtx)
+		cancel()
+		if err != nil {
+			return fmt.Errorf("failed to start service %q: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// Shutdown stops every registered service in reverse start order and runs
+// all BeforeExit hooks concurrently, all bounded by ctx. Services and hooks
+// that fail to stop cleanly are logged, not fatal, so the rest of shutdown
+// still runs. It reports how many goroutines are still running once
+// everything it knows about has been asked to stop.
+func (m *Manager) Shutdown(ctx context.Context) {
+	order, err := m.startOrder()
+	if err != nil {
+		log.WithError(err).Error("Failed to compute shutdown order")
+		order = m.order
+	}
+
+	var hooks sync.WaitGroup
+	for _, fn := range m.beforeExit {
+		hooks.Add(1)
+		go func(fn func()) {
+			defer hooks.Done()
+			fn()
+		}(fn)
+	}
+
+	for i := len(order) - 1; i >= 0; i-- {
+		svc := m.services[order[i]]
+		if svc.stop == nil {
+			continue
+		}
+
+		log.Infof("Stopping service %q...", svc.name)
+		stopCtx, cancel := context.WithTimeout(ctx, m.stopTimeout)
+		err := svc.stop(stopCtx)
+		cancel()
+		if err != nil {
+			log.WithError(err).Errorf("Service %q failed to stop cleanly", svc.name)
+		} else {
+			log.Infof("Service %q stopped successfully", svc.name)
+		}
+	}
+
+	hooksDone := make(chan struct{})
+	go func() {
+		hooks.Wait()
+		close(hooksDone)
+	}()
+
+	select {
+	case <-hooksDone:
+	case <-ctx.Done():
+		log.Warn("Shutdown deadline reached before all BeforeExit hooks completed")
+	}
+
+	if n := runtime.NumGoroutine(); n > 1 {
+		log.Infof("%d goroutines still running after shutdown", n)
+	}
+}
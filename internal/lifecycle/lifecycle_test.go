@@ -0,0 +1,130 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestStartOrderRespectsDependencies(t *testing.T) {
+	m := New(time.Second)
+	m.Register("database", nil, nil)
+	m.Register("cache", nil, nil, "database")
+	m.Register("api", nil, nil, "cache", "database")
+
+	order, err := m.startOrder()
+	if err != nil {
+		t.Fatalf("startOrder() error = %v", err)
+	}
+
+	pos := make(map[string]int, len(order))
+	for i, name := range order {
+		pos[name] = i
+	}
+	if pos["database"] > pos["cache"] {
+		t.Fatalf("database must start before cache, got order %v", order)
+	}
+	if pos["cache"] > pos["api"] {
+		t.Fatalf("cache must start before api, got order %v", order)
+	}
+}
+
+func TestStartOrderDetectsCycle(t *testing.T) {
+	m := New(time.Second)
+	m.Register("a", nil, nil, "b")
+	m.Register("b", nil, nil, "a")
+
+	if _, err := m.startOrder(); err == nil {
+		t.Fatal("startOrder() expected error for circular dependency, got nil")
+	}
+}
+
+func TestStartOrderUnknownDependency(t *testing.T) {
+	m := New(time.Second)
+	m.Register("api", nil, nil, "does-not-exist")
+
+	if _, err := m.startOrder(); err == nil {
+		t.Fatal("startOrder() expected error for unknown dependency, got nil")
+	}
+}
+
+func TestStartAndShutdownOrder(t *testing.T) {
+	m := New(time.Second)
+
+	var started, stopped []string
+
+	m.Register("database",
+		func(ctx context.Context) error { started = append(started, "database"); return nil },
+		func(ctx context.Context) error { stopped = append(stopped, "database"); return nil },
+	)
+	m.Register("api",
+		func(ctx context.Context) error { started = append(started, "api"); return nil },
+		func(ctx context.Context) error { stopped = append(stopped, "api"); return nil },
+		"database",
+	)
+
+	if err := m.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	if got, want := started, []string{"database", "api"}; !equal(got, want) {
+		t.Fatalf("start order = %v, want %v", got, want)
+	}
+
+	m.Shutdown(context.Background())
+	if got, want := stopped, []string{"api", "database"}; !equal(got, want) {
+		t.Fatalf("shutdown order = %v, want %v", got, want)
+	}
+}
+
+func TestStartStopsAtFirstFailure(t *testing.T) {
+	m := New(time.Second)
+
+	var started []string
+	wantErr := errors.New("boom")
+
+	m.Register("database",
+		func(ctx context.Context) error { started = append(started, "database"); return wantErr },
+		nil,
+	)
+	m.Register("api",
+		func(ctx context.Context) error { started = append(started, "api"); return nil },
+		nil,
+		"database",
+	)
+
+	err := m.Start(context.Background())
+	if err == nil {
+		t.Fatal("Start() expected error, got nil")
+	}
+	if got, want := started, []string{"database"}; !equal(got, want) {
+		t.Fatalf("started services = %v, want %v (api must not start after database fails)", got, want)
+	}
+}
+
+func TestBeforeExitHooksRunOnShutdown(t *testing.T) {
+	m := New(time.Second)
+
+	hookRan := make(chan struct{})
+	m.BeforeExit(func() { close(hookRan) })
+
+	m.Shutdown(context.Background())
+
+	select {
+	case <-hookRan:
+	default:
+		t.Fatal("BeforeExit hook did not run during Shutdown")
+	}
+}
+
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
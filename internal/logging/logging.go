@@ -0,0 +1,231 @@
+// Package logging provides the structured logger used throughout the agent.
+// It supports per-subsystem levels, arbitrary fields, and pluggable output
+// sinks (stdout JSON, rotating file, syslog), configured once via
+// Initialize and then used through the package-level helpers or a child
+// logger returned by WithFields/WithComponent.
+package logging
+
+import (
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/cjlapao/lxc-agent/internal/config"
+)
+
+// Level is the severity of a log entry.
+type Level int
+
+const (
+	DebugLevel Level = iota
+	InfoLevel
+	WarnLevel
+	ErrorLevel
+	FatalLevel
+)
+
+// String renders the level the way it appears in log output.
+func (l Level) String() string {
+	switch l {
+	case DebugLevel:
+		return "debug"
+	case InfoLevel:
+		return "info"
+	case WarnLevel:
+		return "warn"
+	case ErrorLevel:
+		return "error"
+	case FatalLevel:
+		return "fatal"
+	default:
+		return "unknown"
+	}
+}
+
+func parseLevel(s string) (Level, bool) {
+	switch s {
+	case "debug":
+		return DebugLevel, true
+	case "info":
+		return InfoLevel, true
+	case "warn", "warning":
+		return WarnLevel, true
+	case "error":
+		return ErrorLevel, true
+	case "fatal":
+		return FatalLevel, true
+	default:
+		return InfoLevel, false
+	}
+}
+
+// Entry is a single log line handed to every configured Sink.
+type Entry struct {
+	Level     Level
+	Message   string
+	Component string
+	Fields    map[string]any
+}
+
+// Sink receives every entry that passes the effective level check.
+type Sink interface {
+	Write(Entry) error
+}
+
+// Logger is the structured logging interface used across the agent.
+// WithFields, WithComponent, and WithError return a child logger that
+// carries the additional context into every subsequent call without
+// mutating the receiver.
+type Logger interface {
+	Debug(msg string)
+	Debugf(format string, args ...any)
+	Info(msg string)
+	Infof(format string, args ...any)
+	Warn(msg string)
+	Warnf(format string, args ...any)
+	Error(msg string)
+	Errorf(format string, args ...any)
+	// Fatalf logs at FatalLevel and then calls os.Exit(1).
+	Fatalf(format string, args ...any)
+	WithError(err error) Logger
+	WithFields(fields map[string]any) Logger
+	WithComponent(component string) Logger
+}
+
+type logger struct {
+	manager   *manager
+	component string
+	fields    map[string]any
+}
+
+type manager struct {
+	mu         sync.RWMutex
+	sinks      []Sink
+	defaultLvl Level
+	subsystems map[string]Level
+}
+
+func (m *manager) levelFor(component string) Level {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if component != "" {
+		if lvl, ok := m.subsystems[component]; ok {
+			return lvl
+		}
+	}
+	return m.defaultLvl
+}
+
+func (m *manager) write(entry Entry) {
+	m.mu.RLock()
+	sinks := m.sinks
+	m.mu.RUnlock()
+
+	for _, sink := range sinks {
+		if err := sink.Write(entry); err != nil {
+			fmt.Fprintf(os.Stderr, "logging: sink write failed: %v\n", err)
+		}
+	}
+}
+
+// defaultManager starts with a plain stdout sink so calls made before
+// Initialize (or when config.Initialize itself fails) still show up
+// somewhere, instead of silently dropping.
+var defaultManager = &manager{
+	defaultLvl: InfoLevel,
+	subsystems: map[string]Level{},
+	sinks:      []Sink{newStdoutSink()},
+}
+
+var defaultLogger Logger = &logger{manager: defaultManager}
+
+// Initialize configures the default logger from config: the active sinks
+// (logging.sinks, defaulting to stdout JSON), the global level
+// (logging.level), and any per-subsystem overrides set via
+// logging.subsystems.<name>=<level>.
+func Initialize() {
+	cfg := config.GetInstance().Get()
+
+	if lvl, ok := parseLevel(cfg.Get("logging.level").GetString()); ok {
+		defaultManager.defaultLvl = lvl
+	}
+
+	defaultManager.sinks = buildSinks(cfg)
+	defaultManager.subsystems = loadSubsystemLevels(cfg)
+}
+
+func loadSubsystemLevels(cfg *config.Config) map[string]Level {
+	levels := map[string]Level{}
+	for _, name := range cfg.Get("logging.subsystems").GetMapKeys() {
+		if lvl, ok := parseLevel(cfg.Get("logging.subsystems." + name).GetString()); ok {
+			levels[name] = lvl
+		}
+	}
+	return levels
+}
+
+func (l *logger) log(level Level, msg string) {
+	if level < l.manager.levelFor(l.component) {
+		return
+	}
+
+	l.manager.write(Entry{
+		Level:     level,
+		Message:   msg,
+		Component: l.component,
+		Fields:    l.fields,
+	})
+
+	if level == FatalLevel {
+		os.Exit(1)
+	}
+}
+
+func (l *logger) Debug(msg string)                  { l.log(DebugLevel, msg) }
+func (l *logger) Debugf(format string, args ...any) { l.log(DebugLevel, fmt.Sprintf(format, args...)) }
+func (l *logger) Info(msg string)                   { l.log(InfoLevel, msg) }
+func (l *logger) Infof(format string, args ...any)  { l.log(InfoLevel, fmt.Sprintf(format, args...)) }
+func (l *logger) Warn(msg string)                   { l.log(WarnLevel, msg) }
+func (l *logger) Warnf(format string, args ...any)  { l.log(WarnLevel, fmt.Sprintf(format, args...)) }
+func (l *logger) Error(msg string)                  { l.log(ErrorLevel, msg) }
+func (l *logger) Errorf(format string, args ...any) { l.log(ErrorLevel, fmt.Sprintf(format, args...)) }
+
+// Fatalf logs at FatalLevel and calls os.Exit(1) in one step, replacing the
+// Errorf-then-os.Exit(1) pattern previously repeated at every call site.
+func (l *logger) Fatalf(format string, args ...any) { l.log(FatalLevel, fmt.Sprintf(format, args...)) }
+
+func (l *logger) WithError(err error) Logger {
+	return l.WithFields(map[string]any{"error": err.Error()})
+}
+
+func (l *logger) WithFields(fields map[string]any) Logger {
+	merged := make(map[string]any, len(l.fields)+len(fields))
+	for k, v := range l.fields {
+		merged[k] = v
+	}
+	for k, v := range fields {
+		merged[k] = v
+	}
+	return &logger{manager: l.manager, component: l.component, fields: merged}
+}
+
+func (l *logger) WithComponent(component string) Logger {
+	return &logger{manager: l.manager, component: component, fields: l.fields}
+}
+
+// Package-level helpers delegate to the default logger so existing call
+// sites (logging.Info(...), logging.Errorf(...), ...) keep working
+// unchanged.
+
+func Debug(msg string)                        { defaultLogger.Debug(msg) }
+func Debugf(format string, args ...any)       { defaultLogger.Debugf(format, args...) }
+func Info(msg string)                         { defaultLogger.Info(msg) }
+func Infof(format string, args ...any)        { defaultLogger.Infof(format, args...) }
+func Warn(msg string)                         { defaultLogger.Warn(msg) }
+func Warnf(format string, args ...any)        { defaultLogger.Warnf(format, args...) }
+func Error(msg string)                        { defaultLogger.Error(msg) }
+func Errorf(format string, args ...any)       { defaultLogger.Errorf(format, args...) }
+func Fatalf(format string, args ...any)       { defaultLogger.Fatalf(format, args...) }
+func WithError(err error) Logger              { return defaultLogger.WithError(err) }
+func WithFields(fields map[string]any) Logger { return defaultLogger.WithFields(fields) }
+func WithComponent(component string) Logger   { return defaultLogger.WithComponent(component) }
@@ -0,0 +1,103 @@
+package logging
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/cjlapao/lxc-agent/internal/config"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// buildSinks constructs the active sink list from logging.sinks (a
+// comma-separated list of stdout, file, syslog). It always falls back to a
+// single stdout JSON sink when nothing is configured.
+func buildSinks(cfg *config.Config) []Sink {
+	names := cfg.Get("logging.sinks").GetStringSlice()
+	if len(names) == 0 {
+		names = []string{"stdout"}
+	}
+
+	sinks := make([]Sink, 0, len(names))
+	for _, name := range names {
+		switch name {
+		case "stdout":
+			sinks = append(sinks, newStdoutSink())
+		case "file":
+			sinks = append(sinks, newFileSink(cfg))
+		case "syslog":
+			sink, err := newSyslogSink(cfg)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "logging: failed to initialize syslog sink: %v\n", err)
+				continue
+			}
+			sinks = append(sinks, sink)
+		default:
+			fmt.Fprintf(os.Stderr, "logging: unknown sink %q, ignoring\n", name)
+		}
+	}
+
+	if len(sinks) == 0 {
+		sinks = append(sinks, newStdoutSink())
+	}
+	return sinks
+}
+
+// stdoutSink writes each entry as a single JSON line to stdout.
+type stdoutSink struct {
+	enc *json.Encoder
+}
+
+func newStdoutSink() *stdoutSink {
+	return &stdoutSink{enc: json.NewEncoder(os.Stdout)}
+}
+
+func (s *stdoutSink) Write(entry Entry) error {
+	return s.enc.Encode(entryToLine(entry))
+}
+
+// fileSink writes JSON lines to a rotating log file on disk.
+// Rotation is handled by lumberjack using logging.file.* config.
+type fileSink struct {
+	writer *lumberjack.Logger
+	enc    *json.Encoder
+}
+
+func newFileSink(cfg *config.Config) *fileSink {
+	path := cfg.Get("logging.file.path").GetString()
+	if path == "" {
+		path = "/var/log/container-agent/agent.log"
+	}
+
+	writer := &lumberjack.Logger{
+		Filename:   path,
+		MaxSize:    cfg.Get("logging.file.max_size_mb").GetInt(),
+		MaxBackups: cfg.Get("logging.file.max_backups").GetInt(),
+		MaxAge:     cfg.Get("logging.file.max_age_days").GetInt(),
+		Compress:   cfg.Get("logging.file.compress").GetBool(),
+	}
+	return &fileSink{writer: writer, enc: json.NewEncoder(writer)}
+}
+
+func (s *fileSink) Write(entry Entry) error {
+	return s.enc.Encode(entryToLine(entry))
+}
+
+type jsonLine struct {
+	Time      string         `json:"time"`
+	Level     string         `json:"level"`
+	Component string         `json:"component,omitempty"`
+	Message   string         `json:"message"`
+	Fields    map[string]any `json:"fields,omitempty"`
+}
+
+func entryToLine(entry Entry) jsonLine {
+	return jsonLine{
+		Time:      time.Now().UTC().Format(time.RFC3339Nano),
+		Level:     entry.Level.String(),
+		Component: entry.Component,
+		Message:   entry.Message,
+		Fields:    entry.Fields,
+	}
+}
@@ -0,0 +1,44 @@
+//go:build linux
+
+package logging
+
+import (
+	"log/syslog"
+
+	"github.com/cjlapao/lxc-agent/internal/config"
+)
+
+// syslogSink forwards entries to the local syslog daemon at a severity
+// matching the entry's level.
+type syslogSink struct {
+	writer *syslog.Writer
+}
+
+func newSyslogSink(cfg *config.Config) (*syslogSink, error) {
+	tag := cfg.Get("logging.syslog.tag").GetString()
+	if tag == "" {
+		tag = "container-agent"
+	}
+
+	writer, err := syslog.New(syslog.LOG_INFO, tag)
+	if err != nil {
+		return nil, err
+	}
+	return &syslogSink{writer: writer}, nil
+}
+
+func (s *syslogSink) Write(entry Entry) error {
+	line := entry.Message
+	switch entry.Level {
+	case DebugLevel:
+		return s.writer.Debug(line)
+	case InfoLevel:
+		return s.writer.Info(line)
+	case WarnLevel:
+		return s.writer.Warning(line)
+	case ErrorLevel, FatalLevel:
+		return s.writer.Err(line)
+	default:
+		return s.writer.Info(line)
+	}
+}
@@ -0,0 +1,48 @@
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/cjlapao/lxc-agent/internal/config"
+)
+
+// legacyConfigKeys maps a secret key to the config key it was read from
+// directly before the secrets package existed. Deployments that already
+// set these in their config file (rather than the ENCRYPTION_MASTER/
+// ENCRYPTION_GLOBAL env vars this provider otherwise expects) keep working.
+var legacyConfigKeys = map[string]string{
+	"encryption.master": config.EncryptionMasterSecretKey,
+	"encryption.global": config.EncryptionGlobalSecretKey,
+}
+
+// envProvider reads secret "encryption.master" from the ENCRYPTION_MASTER
+// environment variable (dots become underscores, uppercased), falling
+// back to the pre-secrets config key for the handful of keys that used to
+// live directly in config.
+type envProvider struct {
+	legacyConfig *config.Config
+}
+
+func newEnvProvider(legacyConfig *config.Config) *envProvider {
+	return &envProvider{legacyConfig: legacyConfig}
+}
+
+func (p *envProvider) Get(ctx context.Context, key string) (string, error) {
+	envVar := strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+	if value, ok := os.LookupEnv(envVar); ok {
+		return value, nil
+	}
+
+	if p.legacyConfig != nil {
+		if legacyKey, ok := legacyConfigKeys[key]; ok {
+			if value := p.legacyConfig.Get(legacyKey).GetString(); value != "" {
+				return value, nil
+			}
+		}
+	}
+
+	return "", fmt.Errorf("secret %q not set (expected env var %s)", key, envVar)
+}
@@ -0,0 +1,31 @@
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// fileProvider reads each secret from its own file under dir, matching
+// how Docker and Kubernetes mount secrets (one file per key).
+type fileProvider struct {
+	dir string
+}
+
+func newFileProvider(dir string) *fileProvider {
+	if dir == "" {
+		dir = "/run/secrets"
+	}
+	return &fileProvider{dir: dir}
+}
+
+func (p *fileProvider) Get(ctx context.Context, key string) (string, error) {
+	path := filepath.Join(p.dir, key)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read secret %q from %s: %w", key, path, err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
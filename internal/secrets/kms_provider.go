@@ -0,0 +1,96 @@
+package secrets
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	gcpkms "cloud.google.com/go/kms/apiv1"
+	gcpkmspb "cloud.google.com/go/kms/apiv1/kmspb"
+	awscfg "github.com/aws/aws-sdk-go-v2/config"
+	awskms "github.com/aws/aws-sdk-go-v2/service/kms"
+)
+
+// kmsProvider reads base64-encoded, KMS-wrapped ciphertext from
+// KMSEncryptedFile/<key> and decrypts it on demand via AWS or GCP KMS.
+type kmsProvider struct {
+	cloud     string
+	keyID     string
+	dir       string
+	awsClient *awskms.Client
+	gcpClient *gcpkms.KeyManagementClient
+}
+
+func newKMSProvider(cfg Config) (*kmsProvider, error) {
+	if cfg.KMSEncryptedFile == "" {
+		return nil, fmt.Errorf("secrets.kms.encrypted_file is required")
+	}
+	if cfg.KMSKeyID == "" {
+		return nil, fmt.Errorf("secrets.kms.key_id is required")
+	}
+
+	p := &kmsProvider{cloud: cfg.KMSCloud, keyID: cfg.KMSKeyID, dir: cfg.KMSEncryptedFile}
+
+	switch cfg.KMSCloud {
+	case "", "aws":
+		p.cloud = "aws"
+		awsConfig, err := awscfg.LoadDefaultConfig(context.Background(), awscfg.WithRegion(cfg.KMSRegion))
+		if err != nil {
+			return nil, fmt.Errorf("failed to load AWS config: %w", err)
+		}
+		p.awsClient = awskms.NewFromConfig(awsConfig)
+	case "gcp":
+		client, err := gcpkms.NewKeyManagementClient(context.Background())
+		if err != nil {
+			return nil, fmt.Errorf("failed to create GCP KMS client: %w", err)
+		}
+		p.gcpClient = client
+	default:
+		return nil, fmt.Errorf("unknown secrets.kms.cloud %q", cfg.KMSCloud)
+	}
+
+	return p, nil
+}
+
+func (p *kmsProvider) Get(ctx context.Context, key string) (string, error) {
+	encoded, err := os.ReadFile(filepath.Join(p.dir, key))
+	if err != nil {
+		return "", fmt.Errorf("failed to read KMS-wrapped secret %q: %w", key, err)
+	}
+
+	ciphertext, err := base64.StdEncoding.DecodeString(string(encoded))
+	if err != nil {
+		return "", fmt.Errorf("failed to decode KMS-wrapped secret %q: %w", key, err)
+	}
+
+	switch p.cloud {
+	case "gcp":
+		return p.decryptGCP(ctx, ciphertext)
+	default:
+		return p.decryptAWS(ctx, ciphertext)
+	}
+}
+
+func (p *kmsProvider) decryptAWS(ctx context.Context, ciphertext []byte) (string, error) {
+	out, err := p.awsClient.Decrypt(ctx, &awskms.DecryptInput{
+		KeyId:          &p.keyID,
+		CiphertextBlob: ciphertext,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt secret via AWS KMS: %w", err)
+	}
+	return string(out.Plaintext), nil
+}
+
+func (p *kmsProvider) decryptGCP(ctx context.Context, ciphertext []byte) (string, error) {
+	resp, err := p.gcpClient.Decrypt(ctx, &gcpkmspb.DecryptRequest{
+		Name:       p.keyID,
+		Ciphertext: ciphertext,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt secret via GCP KMS: %w", err)
+	}
+	return string(resp.Plaintext), nil
+}
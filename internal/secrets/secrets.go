@@ -0,0 +1,113 @@
+// Package secrets abstracts where the agent's encryption master/global
+// secrets come from, so they don't have to live as plain strings in
+// config. The provider is selected with secrets.provider (env, file,
+// vault, or kms); everything else in the agent just calls secrets.Get.
+package secrets
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cjlapao/lxc-agent/internal/config"
+)
+
+// Provider fetches a named secret from wherever it actually lives.
+type Provider interface {
+	// Get returns the current value of key, e.g. "encryption.master".
+	Get(ctx context.Context, key string) (string, error)
+}
+
+// Config selects and configures the active provider.
+type Config struct {
+	// Provider is one of "env", "file", "vault", "kms".
+	Provider string
+
+	// LegacyConfig, when set, is consulted by the env provider for keys
+	// that used to be read straight out of config before secrets existed
+	// (encryption.master/encryption.global via config.EncryptionMasterSecretKey
+	// /config.EncryptionGlobalSecretKey). This keeps deployments that set
+	// those values in their config file instead of ENCRYPTION_MASTER/
+	// ENCRYPTION_GLOBAL working unchanged.
+	LegacyConfig *config.Config
+
+	// File provider settings. Dir defaults to /run/secrets, matching
+	// Docker/Kubernetes secret mounts; each key is read from Dir/<key>.
+	FileDir string
+
+	// Vault provider settings (KV v2).
+	VaultAddress   string
+	VaultMountPath string
+	VaultToken     string
+	VaultRoleID    string
+	VaultSecretID  string
+
+	// KMS provider settings: KMSEncryptedFile is a directory holding one
+	// base64-encoded, KMS-wrapped file per key (KMSEncryptedFile/<key>),
+	// decrypted via AWS or GCP KMS on every Get. Cloud selects which KMS
+	// API to call ("aws" or "gcp"; empty means "aws").
+	KMSCloud         string
+	KMSKeyID         string
+	KMSRegion        string
+	KMSEncryptedFile string
+}
+
+var instance Provider
+
+// Initialize builds the configured provider and installs it as the
+// package-level singleton used by Get.
+func Initialize(cfg Config) (Provider, error) {
+	var provider Provider
+	var err error
+
+	switch cfg.Provider {
+	case "", "env":
+		provider = newEnvProvider(cfg.LegacyConfig)
+	case "file":
+		provider = newFileProvider(cfg.FileDir)
+	case "vault":
+		provider, err = newVaultProvider(cfg)
+	case "kms":
+		provider, err = newKMSProvider(cfg)
+	default:
+		return nil, fmt.Errorf("unknown secrets provider %q", cfg.Provider)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize %s secrets provider: %w", cfg.Provider, err)
+	}
+
+	instance = provider
+	return instance, nil
+}
+
+// GetInstance returns the secrets provider singleton created by
+// Initialize.
+func GetInstance() Provider {
+	return instance
+}
+
+// Get fetches key from the active provider singleton.
+func Get(ctx context.Context, key string) (string, error) {
+	if instance == nil {
+		return "", fmt.Errorf("secrets provider not initialized")
+	}
+	return instance.Get(ctx, key)
+}
+
+// LoadFromConfig builds a Config from the secrets.* keys, the same way
+// other services read their settings off *config.Config.
+func LoadFromConfig(cfg *config.Config) Config {
+	return Config{
+		Provider:         cfg.Get("secrets.provider").GetString(),
+		LegacyConfig:     cfg,
+		FileDir:          cfg.Get("secrets.file.dir").GetString(),
+		VaultAddress:     cfg.Get("secrets.vault.address").GetString(),
+		VaultMountPath:   cfg.Get("secrets.vault.mount_path").GetString(),
+		VaultToken:       cfg.Get("secrets.vault.token").GetString(),
+		VaultRoleID:      cfg.Get("secrets.vault.role_id").GetString(),
+		VaultSecretID:    cfg.Get("secrets.vault.secret_id").GetString(),
+		KMSCloud:         cfg.Get("secrets.kms.cloud").GetString(),
+		KMSKeyID:         cfg.Get("secrets.kms.key_id").GetString(),
+		KMSRegion:        cfg.Get("secrets.kms.region").GetString(),
+		KMSEncryptedFile: cfg.Get("secrets.kms.encrypted_file").GetString(),
+	}
+}
@@ -0,0 +1,72 @@
+package secrets
+
+import (
+	"context"
+	"fmt"
+
+	vault "github.com/hashicorp/vault/api"
+)
+
+// vaultProvider reads secrets from a HashiCorp Vault KV v2 mount,
+// authenticating with either a static token or AppRole credentials.
+type vaultProvider struct {
+	client    *vault.Client
+	mountPath string
+}
+
+func newVaultProvider(cfg Config) (*vaultProvider, error) {
+	if cfg.VaultAddress == "" {
+		return nil, fmt.Errorf("secrets.vault.address is required")
+	}
+
+	client, err := vault.NewClient(&vault.Config{Address: cfg.VaultAddress})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create vault client: %w", err)
+	}
+
+	switch {
+	case cfg.VaultToken != "":
+		client.SetToken(cfg.VaultToken)
+	case cfg.VaultRoleID != "" && cfg.VaultSecretID != "":
+		if err := loginWithAppRole(client, cfg.VaultRoleID, cfg.VaultSecretID); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("secrets.vault requires either a token or an approle role_id/secret_id pair")
+	}
+
+	mountPath := cfg.VaultMountPath
+	if mountPath == "" {
+		mountPath = "secret"
+	}
+
+	return &vaultProvider{client: client, mountPath: mountPath}, nil
+}
+
+func loginWithAppRole(client *vault.Client, roleID, secretID string) error {
+	secret, err := client.Logical().Write("auth/approle/login", map[string]any{
+		"role_id":   roleID,
+		"secret_id": secretID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to authenticate with vault approle: %w", err)
+	}
+	if secret == nil || secret.Auth == nil {
+		return fmt.Errorf("vault approle login returned no auth info")
+	}
+	client.SetToken(secret.Auth.ClientToken)
+	return nil
+}
+
+func (p *vaultProvider) Get(ctx context.Context, key string) (string, error) {
+	secret, err := p.client.KVv2(p.mountPath).Get(ctx, key)
+	if err != nil {
+		return "", fmt.Errorf("failed to read secret %q from vault: %w", key, err)
+	}
+
+	value, ok := secret.Data["value"].(string)
+	if !ok {
+		return "", fmt.Errorf("secret %q in vault has no string \"value\" field", key)
+	}
+	return value, nil
+}
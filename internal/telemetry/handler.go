@@ -0,0 +1,17 @@
+package telemetry
+
+import "github.com/go-chi/chi/v5"
+
+// ApiHandler exposes /metrics on the agent's own API server, in addition
+// to the dedicated telemetry port started by Provider.Start.
+type ApiHandler struct{}
+
+// NewApiHandler builds the telemetry API handler.
+func NewApiHandler() *ApiHandler {
+	return &ApiHandler{}
+}
+
+// RegisterRoutes registers the /metrics endpoint.
+func (h *ApiHandler) RegisterRoutes(router chi.Router) {
+	router.Get("/metrics", HandlerFunc())
+}
@@ -0,0 +1,144 @@
+package telemetry
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "container_agent"
+
+var (
+	// APIRequestsTotal counts every HTTP request the API server serves.
+	APIRequestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "api_requests_total",
+		Help:      "Total number of API requests handled, by method, route, and status code.",
+	}, []string{"method", "route", "status"})
+
+	// APIRequestDuration measures request latency.
+	APIRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "api_request_duration_seconds",
+		Help:      "API request latency in seconds, by method and route.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	// MessageQueueDepth tracks how many messages are waiting per worker.
+	MessageQueueDepth = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "message_queue_depth",
+		Help:      "Number of messages currently queued, by worker.",
+	}, []string{"worker"})
+
+	// MessageProcessingDuration measures how long a worker takes to
+	// process one message.
+	MessageProcessingDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "message_processing_duration_seconds",
+		Help:      "Message processing latency in seconds, by worker.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"worker"})
+
+	// CapsuleInstallDuration measures how long a capsule install takes.
+	CapsuleInstallDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "capsule_install_duration_seconds",
+		Help:      "Capsule install latency in seconds, by runtime (lxc or docker).",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"runtime"})
+
+	// ContainerOperationsTotal counts LXC/Docker operations.
+	ContainerOperationsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "container_operations_total",
+		Help:      "Total number of container operations, by runtime, operation, and outcome.",
+	}, []string{"runtime", "operation", "outcome"})
+
+	// CacheHitsTotal and CacheMissesTotal together derive the cache hit
+	// ratio.
+	CacheHitsTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "cache_hits_total",
+		Help:      "Total number of cache lookups that hit.",
+	})
+	CacheMissesTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "cache_misses_total",
+		Help:      "Total number of cache lookups that missed.",
+	})
+)
+
+func metricsHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+	return mux
+}
+
+// HandlerFunc exposes the same /metrics handler for registration on the
+// agent's own API server, so metrics can be scraped from the regular API
+// port as well as the dedicated telemetry port.
+func HandlerFunc() http.HandlerFunc {
+	return promhttp.Handler().ServeHTTP
+}
+
+// InstrumentHandler wraps an http.Handler so every request it serves is
+// counted and timed under the given route label. The API server's router
+// uses Middleware instead, which covers every route; wrapping a route
+// with both would count its requests twice.
+func InstrumentHandler(route string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+
+		next.ServeHTTP(recorder, r)
+
+		APIRequestsTotal.WithLabelValues(r.Method, route, strconv.Itoa(recorder.status)).Inc()
+		APIRequestDuration.WithLabelValues(r.Method, route).Observe(time.Since(start).Seconds())
+	})
+}
+
+// Middleware instruments every request the API router serves under the
+// same counters and histograms as InstrumentHandler, labeling by the
+// matched route pattern (e.g. "/capsules/{id}") rather than raw path,
+// which would blow up cardinality with path parameters. Register it with
+// router.Use so it wraps every route without each handler having to call
+// InstrumentHandler itself.
+//
+// chi only populates RouteContext.RoutePattern() as it walks its route
+// tree inside ServeHTTP, so the pattern must be read after next.ServeHTTP
+// returns, not before — the *chi.Context is mutated in place during that
+// nested match, and reading it earlier always sees the zero value.
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+
+		next.ServeHTTP(recorder, r)
+
+		route := r.URL.Path
+		if rctx := chi.RouteContext(r.Context()); rctx != nil {
+			if pattern := rctx.RoutePattern(); pattern != "" {
+				route = pattern
+			}
+		}
+
+		APIRequestsTotal.WithLabelValues(r.Method, route, strconv.Itoa(recorder.status)).Inc()
+		APIRequestDuration.WithLabelValues(r.Method, route).Observe(time.Since(start).Seconds())
+	})
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
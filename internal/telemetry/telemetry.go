@@ -0,0 +1,150 @@
+// Package telemetry exposes Prometheus metrics and OpenTelemetry tracing
+// for the agent. Initialize sets up the metrics server and the trace
+// provider; the rest of the codebase records against the package-level
+// helpers in metrics.go and pulls spans from Tracer(). Middleware records
+// metrics only and starts no spans, and no trace context is carried from
+// an HTTP request into an enqueued message, its worker, or a container
+// operation, so each span started from Tracer() stands on its own.
+package telemetry
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/cjlapao/lxc-agent/internal/logging"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/cjlapao/lxc-agent"
+
+// log tags this package's logging with component "telemetry", so
+// logging.subsystems.telemetry=<level> can raise or lower its verbosity
+// independently of the rest of the agent.
+var log = logging.WithComponent("telemetry")
+
+// Config gates and configures telemetry collection.
+type Config struct {
+	// Enabled turns on both the Prometheus endpoint and the OTel tracer.
+	// When false, Initialize returns a provider whose helpers are no-ops.
+	Enabled bool
+	// MetricsPort is where /metrics is served, independent of the main API
+	// server's port and prefix. Defaults to 9090.
+	MetricsPort int
+	// OTLPEndpoint is the OTLP/gRPC collector address, e.g. localhost:4317.
+	OTLPEndpoint string
+}
+
+// Provider owns the metrics HTTP server and the OTel tracer provider.
+type Provider struct {
+	cfg    Config
+	server *http.Server
+	tp     *sdktrace.TracerProvider
+}
+
+var instance *Provider = &Provider{cfg: Config{}}
+
+// Initialize builds the telemetry provider from cfg. When disabled, it
+// still returns a usable Provider so call sites don't need to nil-check
+// before recording metrics or starting spans.
+func Initialize(cfg Config) (*Provider, error) {
+	if cfg.MetricsPort == 0 {
+		cfg.MetricsPort = 9090
+	}
+
+	p := &Provider{cfg: cfg}
+	if !cfg.Enabled {
+		instance = p
+		return p, nil
+	}
+
+	tp, err := newTracerProvider(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize tracer provider: %w", err)
+	}
+	p.tp = tp
+	otel.SetTracerProvider(tp)
+
+	p.server = &http.Server{
+		Addr:         fmt.Sprintf(":%d", cfg.MetricsPort),
+		Handler:      metricsHandler(),
+		ReadTimeout:  10 * time.Second,
+		WriteTimeout: 10 * time.Second,
+	}
+
+	instance = p
+	return p, nil
+}
+
+// GetInstance returns the telemetry provider singleton set up by
+// Initialize. Safe to call even if telemetry is disabled or Initialize
+// hasn't run yet; it falls back to a disabled no-op provider.
+func GetInstance() *Provider {
+	return instance
+}
+
+// Start begins serving /metrics in the background. It is a no-op when
+// telemetry is disabled.
+func (p *Provider) Start(context.Context) error {
+	if !p.cfg.Enabled {
+		return nil
+	}
+
+	go func() {
+		log.Infof("Starting telemetry metrics server on %s", p.server.Addr)
+		if err := p.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.WithError(err).Error("Telemetry metrics server error")
+		}
+	}()
+	return nil
+}
+
+// Stop shuts down the metrics server and flushes any pending spans.
+func (p *Provider) Stop(ctx context.Context) error {
+	if !p.cfg.Enabled {
+		return nil
+	}
+
+	if p.server != nil {
+		if err := p.server.Shutdown(ctx); err != nil {
+			return fmt.Errorf("failed to shut down telemetry metrics server: %w", err)
+		}
+	}
+	if p.tp != nil {
+		if err := p.tp.Shutdown(ctx); err != nil {
+			return fmt.Errorf("failed to shut down tracer provider: %w", err)
+		}
+	}
+	return nil
+}
+
+// Tracer returns the agent-wide tracer. It is safe to use even when
+// telemetry is disabled: OTel's global no-op tracer is returned instead.
+func Tracer() trace.Tracer {
+	return otel.Tracer(tracerName)
+}
+
+func newTracerProvider(cfg Config) (*sdktrace.TracerProvider, error) {
+	exporter, err := otlptracegrpc.New(context.Background(), otlptracegrpc.WithEndpoint(cfg.OTLPEndpoint), otlptracegrpc.WithInsecure())
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(
+		semconv.ServiceName("container-agent"),
+	))
+	if err != nil {
+		return nil, err
+	}
+
+	return sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	), nil
+}
@@ -0,0 +1,31 @@
+package tunnel
+
+import "time"
+
+// Config controls whether the agent dials home to a central controller and
+// how the reverse tunnel behaves once connected.
+type Config struct {
+	// Enabled turns the tunnel client on. When false, Initialize returns a
+	// no-op service and no outbound connection is attempted.
+	Enabled bool
+	// ControllerURL is the websocket endpoint of the central controller,
+	// e.g. wss://controller.example.com/tunnel.
+	ControllerURL string
+	// AgentID uniquely identifies this agent to the controller and is
+	// embedded in the JWT used to authenticate the tunnel handshake.
+	AgentID string
+	// SecretKey signs the per-agent JWT presented to the controller on
+	// connect. This is the same key used for local API auth.
+	SecretKey string
+	// LocalAPIAddr is the host:port of the agent's own API server, used as
+	// the proxy target for streams the controller opens with the "api"
+	// destination.
+	LocalAPIAddr string
+	// ReconnectBackoff is the initial delay between reconnect attempts.
+	// It doubles on each consecutive failure, capped at maxReconnectBackoff.
+	// Set via the tunnel.reconnect_backoff config key, an integer number
+	// of seconds.
+	ReconnectBackoff time.Duration
+}
+
+const maxReconnectBackoff = 2 * time.Minute
@@ -0,0 +1,43 @@
+package tunnel
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// ApiHandler exposes read-only tunnel status over the agent's API server.
+type ApiHandler struct {
+	service *Service
+}
+
+// NewApiHandler builds the tunnel API handler around the given service.
+func NewApiHandler(service *Service) *ApiHandler {
+	return &ApiHandler{service: service}
+}
+
+// RegisterRoutes registers the /tunnel/status endpoint.
+func (h *ApiHandler) RegisterRoutes(router chi.Router) {
+	router.Get("/tunnel/status", h.getStatus)
+}
+
+type statusResponse struct {
+	Enabled bool   `json:"enabled"`
+	AgentID string `json:"agent_id,omitempty"`
+	State   string `json:"state"`
+}
+
+func (h *ApiHandler) getStatus(w http.ResponseWriter, r *http.Request) {
+	resp := statusResponse{}
+	if h.service != nil {
+		resp.Enabled = h.service.cfg.Enabled
+		resp.AgentID = h.service.cfg.AgentID
+		resp.State = string(h.service.State())
+	} else {
+		resp.State = string(StateDisconnected)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
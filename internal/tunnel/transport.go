@@ -0,0 +1,148 @@
+package tunnel
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gorilla/websocket"
+	"github.com/hashicorp/yamux"
+)
+
+// destAPI is the preamble the controller sends to reach the agent's own
+// API server. Anything of the form "tcp:<port>" is proxied to that port on
+// localhost, which is how capsule-exposed ports are reached.
+const destAPI = "api"
+
+// controllerConn wraps the websocket link to the controller with a yamux
+// session so the single TCP connection can carry the agent's API traffic
+// and any number of capsule-port forwards at once.
+type controllerConn struct {
+	ws      *websocket.Conn
+	session *yamux.Session
+}
+
+// dialController opens the websocket connection and authenticates with the
+// given bearer token, then wraps it in a yamux client session.
+func dialController(ctx context.Context, controllerURL, token string) (*controllerConn, error) {
+	u, err := url.Parse(controllerURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid controller URL: %w", err)
+	}
+
+	header := http.Header{}
+	header.Set("Authorization", "Bearer "+token)
+
+	dialer := websocket.Dialer{}
+	ws, _, err := dialer.DialContext(ctx, u.String(), header)
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := yamux.Client(ws.NetConn(), yamux.DefaultConfig())
+	if err != nil {
+		ws.Close()
+		return nil, fmt.Errorf("failed to establish tunnel session: %w", err)
+	}
+
+	return &controllerConn{ws: ws, session: session}, nil
+}
+
+// Serve accepts multiplexed streams opened by the controller and proxies
+// each one to the local API server or a capsule-exposed port, until ctx is
+// cancelled or the session closes.
+func (c *controllerConn) Serve(ctx context.Context, localAPIAddr string) error {
+	done := make(chan error, 1)
+	go func() {
+		for {
+			stream, err := c.session.Accept()
+			if err != nil {
+				done <- err
+				return
+			}
+			go serveStream(stream, localAPIAddr)
+		}
+	}()
+
+	select {
+	case <-ctx.Done():
+		return nil
+	case err := <-done:
+		return err
+	}
+}
+
+// serveStream reads the destination preamble the controller writes as the
+// first line of the stream, dials the matching local target, and then
+// proxies raw bytes between the two until both directions finish. Two
+// destinations are supported: "api" for the agent's own API server, and
+// "tcp:<port>" for a capsule-exposed port on localhost.
+func serveStream(stream *yamux.Stream, localAPIAddr string) {
+	defer stream.Close()
+
+	reader := bufio.NewReader(stream)
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		log.WithError(err).Error("Tunnel stream closed before destination preamble")
+		return
+	}
+	dest := strings.TrimSpace(line)
+
+	target, ok := resolveTarget(dest, localAPIAddr)
+	if !ok {
+		log.Errorf("Tunnel stream requested unknown destination %q", dest)
+		return
+	}
+
+	conn, err := net.Dial("tcp", target)
+	if err != nil {
+		log.WithError(err).Errorf("Failed to dial tunnel destination %q at %s", dest, target)
+		return
+	}
+	defer conn.Close()
+
+	proxy(stream, reader, conn)
+}
+
+// resolveTarget maps a stream's destination preamble to the local address
+// to dial: destAPI routes to the agent's own API server, and "tcp:<port>"
+// routes to that port on localhost, which is how capsule-exposed ports are
+// reached. ok is false if dest doesn't match either form.
+func resolveTarget(dest, localAPIAddr string) (target string, ok bool) {
+	if dest == destAPI {
+		return localAPIAddr, true
+	}
+
+	port := strings.TrimPrefix(dest, "tcp:")
+	if port == dest {
+		return "", false
+	}
+	return net.JoinHostPort("127.0.0.1", port), true
+}
+
+// proxy copies bytes in both directions between the controller's stream
+// and the locally dialed connection, returning only once both copies have
+// finished: one side closing does not end the other direction. reader may
+// already hold buffered bytes read past the preamble, so it is drained
+// into conn before the raw stream takes over.
+func proxy(stream *yamux.Stream, reader *bufio.Reader, conn net.Conn) {
+	streamDone := make(chan struct{})
+	go func() {
+		defer close(streamDone)
+		io.Copy(conn, reader)
+	}()
+
+	io.Copy(stream, conn)
+	<-streamDone
+}
+
+// Close tears down the yamux session and underlying websocket connection.
+func (c *controllerConn) Close() error {
+	_ = c.session.Close()
+	return c.ws.Close()
+}
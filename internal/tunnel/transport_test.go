@@ -0,0 +1,49 @@
+package tunnel
+
+import "testing"
+
+func TestResolveTarget(t *testing.T) {
+	tests := []struct {
+		name         string
+		dest         string
+		localAPIAddr string
+		wantTarget   string
+		wantOK       bool
+	}{
+		{
+			name:         "api destination routes to local API address",
+			dest:         "api",
+			localAPIAddr: "127.0.0.1:8080",
+			wantTarget:   "127.0.0.1:8080",
+			wantOK:       true,
+		},
+		{
+			name:       "tcp destination routes to that port on localhost",
+			dest:       "tcp:9000",
+			wantTarget: "127.0.0.1:9000",
+			wantOK:     true,
+		},
+		{
+			name:   "unknown destination is rejected",
+			dest:   "bogus",
+			wantOK: false,
+		},
+		{
+			name:   "empty destination is rejected",
+			dest:   "",
+			wantOK: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target, ok := resolveTarget(tt.dest, tt.localAPIAddr)
+			if ok != tt.wantOK {
+				t.Fatalf("resolveTarget(%q) ok = %v, want %v", tt.dest, ok, tt.wantOK)
+			}
+			if ok && target != tt.wantTarget {
+				t.Fatalf("resolveTarget(%q) target = %q, want %q", tt.dest, target, tt.wantTarget)
+			}
+		})
+	}
+}
@@ -0,0 +1,194 @@
+// Package tunnel implements a reverse-tunnel client that lets an agent
+// running on a private LXC host dial out to a central controller and expose
+// its API and capsule-published ports without an inbound public IP. The
+// design mirrors Chisel/Portainer-style tunneling: a single outbound
+// websocket connection is multiplexed to carry both the agent's own API
+// traffic and per-capsule port forwards requested by the controller.
+package tunnel
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	agentcontext "github.com/cjlapao/lxc-agent/internal/context"
+	"github.com/cjlapao/lxc-agent/internal/events"
+	"github.com/cjlapao/lxc-agent/internal/logging"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// log tags every log line this package emits with component "tunnel", so
+// logging.subsystems.tunnel=<level> can raise or lower its verbosity
+// independently of the rest of the agent.
+var log = logging.WithComponent("tunnel")
+
+// State describes the current connectivity of the tunnel client.
+type State string
+
+const (
+	StateDisconnected State = "disconnected"
+	StateConnecting   State = "connecting"
+	StateConnected    State = "connected"
+)
+
+// Service manages the outbound connection to the controller, reconnecting
+// with backoff whenever the link drops.
+type Service struct {
+	cfg Config
+
+	mu    sync.RWMutex
+	state State
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+var instance *Service
+
+// Initialize builds the tunnel service singleton from cfg. It does not
+// connect to the controller; call Start to begin dialing.
+func Initialize(cfg Config) (*Service, error) {
+	if cfg.Enabled {
+		if cfg.ControllerURL == "" {
+			return nil, fmt.Errorf("tunnel.controller_url is required when tunnel.enabled is true")
+		}
+		if cfg.AgentID == "" {
+			return nil, fmt.Errorf("tunnel.agent_id is required when tunnel.enabled is true")
+		}
+		if cfg.ReconnectBackoff <= 0 {
+			cfg.ReconnectBackoff = 2 * time.Second
+		}
+	}
+
+	instance = &Service{
+		cfg:   cfg,
+		state: StateDisconnected,
+	}
+	return instance, nil
+}
+
+// GetInstance returns the tunnel service singleton created by Initialize.
+func GetInstance() *Service {
+	return instance
+}
+
+// Start begins the connect/reconnect loop in the background. It returns
+// immediately if the tunnel is disabled in config.
+func (s *Service) Start(ctx *agentcontext.ApiContext) error {
+	if !s.cfg.Enabled {
+		log.Info("Tunnel service disabled, skipping controller connection")
+		return nil
+	}
+
+	runCtx, cancel := context.WithCancel(ctx)
+	s.cancel = cancel
+	s.done = make(chan struct{})
+
+	go s.run(runCtx)
+	return nil
+}
+
+// Stop cancels the reconnect loop and waits for the current connection to
+// close.
+func (s *Service) Stop() error {
+	if s.cancel == nil {
+		return nil
+	}
+	s.cancel()
+	<-s.done
+	return nil
+}
+
+// State returns the current connectivity state.
+func (s *Service) State() State {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.state
+}
+
+func (s *Service) setState(state State) {
+	s.mu.Lock()
+	s.state = state
+	s.mu.Unlock()
+
+	if svc := events.GetGlobalService(); svc != nil {
+		svc.Publish(events.Event{
+			Type: "tunnel.state_changed",
+			Data: map[string]any{
+				"agent_id": s.cfg.AgentID,
+				"state":    string(state),
+			},
+		})
+	}
+}
+
+func (s *Service) run(ctx context.Context) {
+	defer close(s.done)
+
+	backoff := s.cfg.ReconnectBackoff
+	for {
+		s.setState(StateConnecting)
+		established := false
+		err := s.connect(ctx, &established)
+		s.setState(StateDisconnected)
+
+		if established {
+			// The controller accepted us at least once this attempt, so
+			// treat the link as solid and don't let a later blip cost us
+			// the full backoff climb we built up before.
+			backoff = s.cfg.ReconnectBackoff
+		}
+		if err != nil {
+			log.WithError(err).Errorf("Tunnel connection to %s failed, retrying in %s", s.cfg.ControllerURL, backoff)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+		if backoff > maxReconnectBackoff {
+			backoff = maxReconnectBackoff
+		}
+	}
+}
+
+// connect dials the controller, authenticates with a signed JWT, and blocks
+// serving the multiplexed tunnel until the connection drops or ctx is
+// cancelled. established is set to true once the controller accepts the
+// connection, so the caller can tell a later error apart from one that
+// never got off the ground.
+func (s *Service) connect(ctx context.Context, established *bool) error {
+	token, err := s.signAgentToken()
+	if err != nil {
+		return fmt.Errorf("failed to sign agent token: %w", err)
+	}
+
+	log.Infof("Dialing tunnel controller %s as agent %s", s.cfg.ControllerURL, s.cfg.AgentID)
+
+	conn, err := dialController(ctx, s.cfg.ControllerURL, token)
+	if err != nil {
+		return fmt.Errorf("failed to dial controller: %w", err)
+	}
+	defer conn.Close()
+
+	*established = true
+	s.setState(StateConnected)
+	log.Info("Tunnel connected to controller")
+
+	return conn.Serve(ctx, s.cfg.LocalAPIAddr)
+}
+
+// signAgentToken builds a short-lived JWT identifying this agent to the
+// controller, signed with the same secret used for local API auth.
+func (s *Service) signAgentToken() (string, error) {
+	claims := jwt.MapClaims{
+		"agent_id": s.cfg.AgentID,
+		"exp":      time.Now().Add(5 * time.Minute).Unix(),
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString([]byte(s.cfg.SecretKey))
+}
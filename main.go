@@ -4,12 +4,13 @@ package main
 
 import (
 	sys_context "context"
-	"flag"
 	"fmt"
+	"net"
 	"net/http"
 	_ "net/http/pprof"
 	"os"
 	"os/signal"
+	"strconv"
 	"syscall"
 	"time"
 
@@ -26,73 +27,41 @@ import (
 	"github.com/cjlapao/lxc-agent/internal/encryption"
 	"github.com/cjlapao/lxc-agent/internal/events"
 	"github.com/cjlapao/lxc-agent/internal/executor"
+	"github.com/cjlapao/lxc-agent/internal/lifecycle"
 	"github.com/cjlapao/lxc-agent/internal/logging"
 	"github.com/cjlapao/lxc-agent/internal/lxc"
 	"github.com/cjlapao/lxc-agent/internal/message_processor"
+	"github.com/cjlapao/lxc-agent/internal/secrets"
+	"github.com/cjlapao/lxc-agent/internal/telemetry"
+	"github.com/cjlapao/lxc-agent/internal/tunnel"
 	"github.com/cjlapao/lxc-agent/internal/validation"
-	"github.com/cjlapao/lxc-agent/pkg/version"
 )
 
 // Version is set at build time via ldflags
 var Version = "unknown"
 
+// secretsLog tags secrets/encryption lifecycle logging with component
+// "secrets", so logging.subsystems.secrets=<level> can be raised
+// independently when diagnosing provider or rotation issues.
+var secretsLog = logging.WithComponent("secrets")
+
 const (
 	// AppName is the name of the application
 	AppName = "Container Agent"
 )
 
 func main() {
-	// Initialize configuration service first
-	if err := config.Initialize(); err != nil {
-		fmt.Printf("Error initializing config: %v\n", err)
-		os.Exit(1)
-	}
-
-	// Initialize logging service
-	logging.Initialize()
-
-	// Display startup banner with version information
-	version.ShowStartupBanner(Version, AppName)
-
-	logging.Info("Starting Container Agent...")
-
-	// Define command line flags
-	var (
-		showVersion = flag.Bool("version", false, "Show version information")
-		showHelp    = flag.Bool("help", false, "Show help information")
-	)
-
-	// Parse command line arguments
-	flag.Parse()
-
-	// Handle version flag
-	if *showVersion {
-		version.ShowVersionFlag(Version, AppName)
-		os.Exit(0)
-	}
-
-	// Handle help flag
-	if *showHelp {
-		showUsage()
-		os.Exit(0)
-	}
-
-	cfg := config.GetInstance().Get()
-
-	// Initialize services
-	if err := run(cfg); err != nil {
-		logging.Errorf("Error: %v", err)
-		os.Exit(1)
+	app := buildCLI()
+	if err := app.Run(os.Args); err != nil {
+		logging.Fatalf("Error: %v", err)
 	}
 }
 
-// initializeDatabase initializes the database service
-func initializeDatabase(cfg *config.Config) error {
-	logging.Info("Initializing database service...")
-	storagePath, err := config.GetInstance().GetStoragePath()
-	if err != nil {
-		return fmt.Errorf("failed to get storage path: %w", err)
-	}
+// buildDatabaseConfig validates cfg's database.* keys and translates them
+// into a database.Config, without opening a connection. initializeDatabase
+// and the "config validate" CLI subcommand both build on this so the
+// required-field checks only live in one place.
+func buildDatabaseConfig(cfg *config.Config) (database.Config, error) {
 	var dbConfig database.Config
 	if cfg.Get(config.DatabaseTypeKey).GetString() == "postgres" {
 		dbConfig.Type = database.PostgreSQL
@@ -103,26 +72,39 @@ func initializeDatabase(cfg *config.Config) error {
 		dbConfig.Password = cfg.Get(config.DatabasePasswordKey).GetString()
 		dbConfig.SSLMode = cfg.Get(config.DatabaseSSLModeKey).GetBool()
 		if dbConfig.Database == "" {
-			return fmt.Errorf("database name is required")
+			return dbConfig, fmt.Errorf("database name is required")
 		}
 		if dbConfig.Username == "" {
-			return fmt.Errorf("database username is required")
+			return dbConfig, fmt.Errorf("database username is required")
 		}
 		if dbConfig.Password == "" {
-			return fmt.Errorf("database password is required")
+			return dbConfig, fmt.Errorf("database password is required")
 		}
 		if dbConfig.Host == "" {
-			return fmt.Errorf("database host is required")
+			return dbConfig, fmt.Errorf("database host is required")
 		}
 		if dbConfig.Port == 0 {
 			dbConfig.Port = 5432
 		}
 	} else {
+		storagePath, err := config.GetInstance().GetStoragePath()
+		if err != nil {
+			return dbConfig, fmt.Errorf("failed to get storage path: %w", err)
+		}
 		dbConfig.Type = database.SQLite
 		dbConfig.StoragePath = storagePath
-
 	}
 	dbConfig.Debug = cfg.Get(config.DebugKey).GetBool()
+	return dbConfig, nil
+}
+
+// initializeDatabase initializes the database service
+func initializeDatabase(cfg *config.Config) error {
+	logging.Info("Initializing database service...")
+	dbConfig, err := buildDatabaseConfig(cfg)
+	if err != nil {
+		return err
+	}
 
 	if err := database.Initialize(&dbConfig); err != nil {
 		return fmt.Errorf("failed to initialize database: %w", err)
@@ -192,16 +174,88 @@ func initializeMessageProcessorService(store *stores.MessageDataStore) (*message
 	return svc, nil
 }
 
-// initializeEncryptionService initializes the encryption service
-func initializeEncryptionService(cfg *config.Config) error {
-	logging.Info("Initializing encryption service...")
+// reportMessageQueueDepth polls the message processor's per-worker queue
+// depth and publishes it to telemetry.MessageQueueDepth, since the worker
+// dispatch loop itself lives inside message_processor and isn't something
+// main.go can instrument inline.
+func reportMessageQueueDepth(ctx *context.ApiContext, svc *message_processor.MessageProcessorService) {
+	ticker := time.NewTicker(10 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for worker, depth := range svc.QueueDepths() {
+				telemetry.MessageQueueDepth.WithLabelValues(worker).Set(float64(depth))
+			}
+		}
+	}
+}
+
+// initializeSecretsService initializes the provider used to fetch the
+// encryption master/global secrets, selected via secrets.provider.
+func initializeSecretsService(cfg *config.Config) (secrets.Provider, error) {
+	secretsLog.Info("Initializing secrets service...")
+	provider, err := secrets.Initialize(secrets.LoadFromConfig(cfg))
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize secrets service: %w", err)
+	}
+	secretsLog.Info("Secrets service initialized successfully")
+	return provider, nil
+}
+
+// initializeEncryptionService initializes the encryption service, fetching
+// its master/global secrets from the configured secrets provider rather
+// than reading them directly out of config.
+func initializeEncryptionService(ctx sys_context.Context) error {
+	secretsLog.Info("Initializing encryption service...")
+	masterSecret, err := secrets.Get(ctx, "encryption.master")
+	if err != nil {
+		return fmt.Errorf("failed to fetch encryption master secret: %w", err)
+	}
+	globalSecret, err := secrets.Get(ctx, "encryption.global")
+	if err != nil {
+		return fmt.Errorf("failed to fetch encryption global secret: %w", err)
+	}
+
 	if err := encryption.Initialize(encryption.Config{
-		MasterSecret: cfg.Get(config.EncryptionMasterSecretKey).GetString(),
-		GlobalSecret: cfg.Get(config.EncryptionGlobalSecretKey).GetString(),
+		MasterSecret: masterSecret,
+		GlobalSecret: globalSecret,
 	}); err != nil {
 		return fmt.Errorf("failed to initialize encryption service: %w", err)
 	}
-	logging.Info("Encryption service initialized successfully")
+	secretsLog.Info("Encryption service initialized successfully")
+	return nil
+}
+
+// rotateEncryptionKeys re-fetches the master secret from the configured
+// provider and, if it has changed, rotates the encryption service to it
+// and re-encrypts at-rest fields in the auth and capsule stores. It is
+// triggered by SIGHUP so keys can be rotated without downtime.
+func rotateEncryptionKeys(ctx sys_context.Context, authDataStore *stores.AuthDataStore, capsuleStore *stores.CapsuleDataStore) error {
+	oldKey := encryption.GetInstance().MasterKey()
+	newKey, err := secrets.Get(ctx, "encryption.master")
+	if err != nil {
+		return fmt.Errorf("failed to refetch encryption master secret: %w", err)
+	}
+	if newKey == oldKey {
+		secretsLog.Info("Encryption master secret unchanged, skipping rotation")
+		return nil
+	}
+
+	secretsLog.Info("Rotating encryption key...")
+	if err := encryption.Rotate(oldKey, newKey); err != nil {
+		return fmt.Errorf("failed to rotate encryption key: %w", err)
+	}
+	if err := authDataStore.ReEncrypt(oldKey, newKey); err != nil {
+		return fmt.Errorf("failed to re-encrypt auth store: %w", err)
+	}
+	if err := capsuleStore.ReEncrypt(oldKey, newKey); err != nil {
+		return fmt.Errorf("failed to re-encrypt capsule store: %w", err)
+	}
+	secretsLog.Info("Encryption key rotated successfully")
 	return nil
 }
 
@@ -281,6 +335,44 @@ func initializeCapsuleClientService(dockerService *docker.DockerService, lxcServ
 	return clientService, nil
 }
 
+// initializeTunnelService initializes the reverse-tunnel client that lets
+// this agent dial home to a central controller instead of requiring an
+// inbound public IP.
+func initializeTunnelService(cfg *config.Config) (*tunnel.Service, error) {
+	logging.Info("Initializing tunnel service...")
+	tunnelService, err := tunnel.Initialize(tunnel.Config{
+		Enabled:          cfg.Get("tunnel.enabled").GetBool(),
+		ControllerURL:    cfg.Get("tunnel.controller_url").GetString(),
+		AgentID:          cfg.Get("tunnel.agent_id").GetString(),
+		SecretKey:        cfg.Get(config.JwtAuthSecretKey).GetString(),
+		ReconnectBackoff: time.Duration(cfg.Get("tunnel.reconnect_backoff").GetInt()) * time.Second,
+		// Dialed from the same host the API server binds to, regardless of
+		// what address it's actually listening on.
+		LocalAPIAddr: net.JoinHostPort("127.0.0.1", strconv.Itoa(cfg.Get(config.ServerAPIPortKey).GetInt())),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize tunnel service: %w", err)
+	}
+	logging.Info("Tunnel service initialized successfully")
+	return tunnelService, nil
+}
+
+// initializeTelemetryService initializes Prometheus metrics collection and
+// OpenTelemetry tracing, gated by telemetry.enabled.
+func initializeTelemetryService(cfg *config.Config) (*telemetry.Provider, error) {
+	logging.Info("Initializing telemetry service...")
+	provider, err := telemetry.Initialize(telemetry.Config{
+		Enabled:      cfg.Get("telemetry.enabled").GetBool(),
+		MetricsPort:  cfg.Get("telemetry.metrics_port").GetInt(),
+		OTLPEndpoint: cfg.Get("telemetry.otlp_endpoint").GetString(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize telemetry service: %w", err)
+	}
+	logging.Info("Telemetry service initialized successfully")
+	return provider, nil
+}
+
 // initializeCaddyService initializes the caddy service
 func initializeCaddyService() (caddy.Service, error) {
 	logging.Info("Initializing caddy service...")
@@ -292,6 +384,234 @@ func initializeCaddyService() (caddy.Service, error) {
 	return caddy.GetInstance(), nil
 }
 
+// registerLifecycle wires every long-lived service into the lifecycle
+// manager with its start/stop functions and dependencies, replacing the
+// fixed sequence that used to live inline in run(). Only events and the
+// API server used to get a graceful stop; now every service that has
+// something to stop does (secrets and encryption register no stop), in
+// the reverse of whatever order it actually started in.
+func registerLifecycle(cfg *config.Config, ctx *context.ApiContext, authDataStoreOut **stores.AuthDataStore, capsuleStoreOut **stores.CapsuleDataStore) *lifecycle.Manager {
+	manager := lifecycle.New(30 * time.Second)
+
+	var (
+		authDataStore           *stores.AuthDataStore
+		capsuleStore            *stores.CapsuleDataStore
+		messageDataStore        *stores.MessageDataStore
+		messageProcessorService *message_processor.MessageProcessorService
+		authService             auth.Service
+		lxcService              *lxc.LxcService
+		dockerService           *docker.DockerService
+		clientService           *capsule.ClientService
+		statsMonitor            *capsule.StatsMonitor
+		tunnelService           *tunnel.Service
+		telemetryProvider       *telemetry.Provider
+		server                  *api.Server
+	)
+
+	manager.Register("secrets",
+		func(sys_context.Context) error {
+			_, err := initializeSecretsService(cfg)
+			return err
+		},
+		nil,
+	)
+
+	manager.Register("encryption",
+		func(ctx sys_context.Context) error { return initializeEncryptionService(ctx) },
+		nil,
+		"secrets",
+	)
+
+	manager.Register("database",
+		func(sys_context.Context) error {
+			if err := initializeDatabase(cfg); err != nil {
+				return err
+			}
+
+			var err error
+			if authDataStore, err = initializeAuthStore(); err != nil {
+				return err
+			}
+			if capsuleStore, err = initializeCapsuleStore(); err != nil {
+				return err
+			}
+			if messageDataStore, err = initializeMessageStore(); err != nil {
+				return err
+			}
+
+			initializeValidationService()
+			authService = initializeAuthService(cfg, authDataStore)
+			*authDataStoreOut = authDataStore
+			*capsuleStoreOut = capsuleStore
+			return nil
+		},
+		func(sys_context.Context) error { return database.Close() },
+		"encryption",
+	)
+
+	manager.Register("cache",
+		func(sys_context.Context) error { return initializeCacheService() },
+		func(sys_context.Context) error { return cache.Close() },
+	)
+
+	manager.Register("events",
+		func(sys_context.Context) error {
+			if err := initializeEventService(); err != nil {
+				return err
+			}
+			return startEventService(ctx)
+		},
+		func(sys_context.Context) error { return events.GetGlobalService().Stop() },
+	)
+
+	manager.Register("lxc",
+		func(sys_context.Context) error {
+			var err error
+			lxcService, err = initializeLxcService()
+			return err
+		},
+		func(sys_context.Context) error { return lxcService.Close() },
+	)
+
+	manager.Register("docker",
+		func(sys_context.Context) error {
+			var err error
+			dockerService, err = initializeDockerService()
+			return err
+		},
+		func(sys_context.Context) error { return dockerService.Close() },
+	)
+
+	manager.Register("caddy",
+		func(sys_context.Context) error {
+			_, err := initializeCaddyService()
+			return err
+		},
+		func(sys_context.Context) error { return caddy.GetInstance().Stop() },
+	)
+
+	manager.Register("telemetry",
+		func(ctx sys_context.Context) error {
+			var err error
+			telemetryProvider, err = initializeTelemetryService(cfg)
+			if err != nil {
+				return err
+			}
+			return telemetryProvider.Start(ctx)
+		},
+		func(ctx sys_context.Context) error { return telemetryProvider.Stop(ctx) },
+	)
+
+	manager.Register("tunnel",
+		func(sys_context.Context) error {
+			var err error
+			tunnelService, err = initializeTunnelService(cfg)
+			if err != nil {
+				return err
+			}
+			return tunnelService.Start(ctx)
+		},
+		func(sys_context.Context) error { return tunnelService.Stop() },
+	)
+
+	manager.Register("message_processor",
+		func(startCtx sys_context.Context) error {
+			var err error
+			messageProcessorService, err = initializeMessageProcessorService(messageDataStore)
+			if err != nil {
+				return err
+			}
+
+			messageProcessorService.RegisterWorker(ctx, message_processor.NewEmailWorker())
+			messageProcessorService.RegisterWorker(ctx, message_processor.NewNotificationWorker())
+			messageProcessorService.RegisterWorker(ctx, capsule.NewInstallCapsuleWorker(dockerService, lxcService, capsuleStore))
+
+			_, span := telemetry.Tracer().Start(startCtx, "message_processor.start")
+			messageProcessorService.Start(ctx)
+			span.End()
+
+			go reportMessageQueueDepth(ctx, messageProcessorService)
+			return nil
+		},
+		func(sys_context.Context) error { return messageProcessorService.Stop() },
+		"database", "lxc", "docker",
+	)
+
+	manager.Register("capsule_client",
+		func(startCtx sys_context.Context) error {
+			var err error
+			clientService, err = initializeCapsuleClientService(dockerService, lxcService, capsuleStore)
+			if err != nil {
+				return err
+			}
+
+			_, span := telemetry.Tracer().Start(startCtx, "capsule_client.start_monitoring")
+			clientService.StartMonitoring(ctx)
+			span.End()
+			return nil
+		},
+		func(sys_context.Context) error { clientService.StopMonitoring(); return nil },
+		"database", "lxc", "docker",
+	)
+
+	manager.Register("stats_monitor",
+		func(sys_context.Context) error {
+			var err error
+			statsMonitor, err = capsule.InitializeStatsMonitor(dockerService, lxcService, capsuleStore)
+			if err != nil {
+				return err
+			}
+
+			statsInterval := config.GetInstance().Get().GetInt("stats.interval_seconds", 1)
+			statsMonitor.Start(ctx, capsule.StatsMonitorConfig{
+				Interval:    time.Duration(statsInterval) * time.Second,
+				MonitorType: capsule.MonitorTypeCapsule,
+			})
+			return nil
+		},
+		func(sys_context.Context) error { statsMonitor.Stop(); return nil },
+		"database", "lxc", "docker",
+	)
+
+	manager.Register("api",
+		func(sys_context.Context) error {
+			var err error
+			server, err = initializeAPIServer(cfg, authService)
+			if err != nil {
+				return err
+			}
+
+			// Every route gets request counts and latency recorded under
+			// telemetry's api_requests_total/api_request_duration_seconds,
+			// labeled by the matched route pattern.
+			server.Use(telemetry.Middleware)
+
+			logging.Info("Registering routes...")
+			server.RegisterRoutes(api.NewHandler())
+			server.RegisterRoutes(auth.NewApiHandler(authService, authDataStore))
+			server.RegisterRoutes(events.NewApiHandler(events.GetGlobalService(), authService))
+			server.RegisterRoutes(lxc.NewApiHandler(lxcService))
+			server.RegisterRoutes(message_processor.NewApiHandler(message_processor.GetInstance()))
+			server.RegisterRoutes(docker.NewApiHandler(dockerService))
+			server.RegisterRoutes(capsule.NewCapsuleApiHandler(capsuleStore, dockerService, lxcService))
+			server.RegisterRoutes(tunnel.NewApiHandler(tunnelService))
+			server.RegisterRoutes(telemetry.NewApiHandler())
+
+			go func() {
+				if err := server.Start(); err != nil {
+					logging.Errorf("Server error: %v", err)
+				}
+			}()
+			return nil
+		},
+		func(ctx sys_context.Context) error { return server.Stop(ctx) },
+		"encryption", "database", "cache", "events", "lxc", "docker", "caddy", "tunnel", "telemetry",
+		"message_processor", "capsule_client", "stats_monitor",
+	)
+
+	return manager
+}
+
 func run(cfg *config.Config) error {
 	logging.Info("Initializing application...")
 
@@ -313,93 +633,15 @@ func run(cfg *config.Config) error {
 		}()
 	}
 
-	if err := initializeEncryptionService(cfg); err != nil {
-		return err
-	}
-
-	// Initializing database services
-	if err := initializeDatabase(cfg); err != nil {
-		return err
-	}
-
-	authDataStore, err := initializeAuthStore()
-	if err != nil {
-		return err
-	}
-
-	capsuleStore, err := initializeCapsuleStore()
-	if err != nil {
-		return err
-	}
-
-	messageDataStore, err := initializeMessageStore()
-	if err != nil {
-		return err
-	}
-
-	initializeValidationService()
-
-	if err := initializeCacheService(); err != nil {
-		return err
-	}
-
-	// Initialize event service singleton
-	if err := initializeEventService(); err != nil {
-		return err
-	}
-
-	// Initialize message processor service
-	messageProcessorService, err := initializeMessageProcessorService(messageDataStore)
-	if err != nil {
-		return err
-	}
-
-	// Initialize auth service
-	authService := initializeAuthService(cfg, authDataStore)
-
-	// Initialize LXC service
-	lxcService, err := initializeLxcService()
-	if err != nil {
-		return err
-	}
-
-	// Initialize Docker service
-	dockerService, err := initializeDockerService()
-	if err != nil {
-		return err
-	}
-
-	// Initialize caddy service
-	_, err = initializeCaddyService()
-	if err != nil {
-		return err
-	}
-
-	// Initialize API server
-	server, err := initializeAPIServer(cfg, authService)
-	if err != nil {
-		return err
-	}
-
-	logging.Info("Registering routes...")
-	// Register health check routes
-	server.RegisterRoutes(api.NewHandler())
-	// Register auth routes
-	server.RegisterRoutes(auth.NewApiHandler(authService, authDataStore))
-	// Register event routes using the global singleton
-	server.RegisterRoutes(events.NewApiHandler(events.GetGlobalService(), authService))
-	// Register LXC routes
-	server.RegisterRoutes(lxc.NewApiHandler(lxcService))
-	// Register message routes
-	server.RegisterRoutes(message_processor.NewApiHandler(message_processor.GetInstance()))
-	// Register Docker routes
-	server.RegisterRoutes(docker.NewApiHandler(dockerService))
-	// Register capsule routes
-	server.RegisterRoutes(capsule.NewCapsuleApiHandler(capsuleStore, dockerService, lxcService))
 	backgroundCtx := sys_context.Background()
 	ctx := context.New(backgroundCtx)
-	// Start event service
-	if err := startEventService(ctx); err != nil {
+
+	var (
+		authDataStore *stores.AuthDataStore
+		capsuleStore  *stores.CapsuleDataStore
+	)
+	manager := registerLifecycle(cfg, ctx, &authDataStore, &capsuleStore)
+	if err := manager.Start(backgroundCtx); err != nil {
 		return err
 	}
 
@@ -411,98 +653,34 @@ func run(cfg *config.Config) error {
 	cfg.Set(config.NetworkIPKey, networkIP)
 	logging.Infof("Network IP: %s", cfg.Get(config.NetworkIPKey).GetString())
 
-	// TODO: Create initial test messages if in debug mode
-	// if cfg.Get(config.DebugKey).GetBool() {
-	//
-	// }
-
-	// TODO: Seed demo data
-	// if cfg.Get(config.SeedDemoDataKey).GetBool() {
-	//
-	// }
-
-	// Registering workers
-	messageProcessorService.RegisterWorker(ctx, message_processor.NewEmailWorker())
-	messageProcessorService.RegisterWorker(ctx, message_processor.NewNotificationWorker())
-	messageProcessorService.RegisterWorker(ctx, capsule.NewInstallCapsuleWorker(dockerService, lxcService, capsuleStore))
-	messageProcessorService.Start(ctx)
-
-	// Registering Capsule client service
-	clientService, err := initializeCapsuleClientService(dockerService, lxcService, capsuleStore)
-	if err != nil {
-		return err
-	}
-	clientService.StartMonitoring(ctx)
-
-	// Initialize and start stats monitor service
-	statsMonitor, err := capsule.InitializeStatsMonitor(dockerService, lxcService, capsuleStore)
-	if err != nil {
-		return err
-	}
-
-	// Get stats configuration
-	statsInterval := config.GetInstance().Get().GetInt("stats.interval_seconds", 1)
-
-	statsMonitor.Start(ctx, capsule.StatsMonitorConfig{
-		Interval:    time.Duration(statsInterval) * time.Second,
-		MonitorType: capsule.MonitorTypeCapsule,
-	})
-
-	// Start server in a goroutine
-	go func() {
-		if err := server.Start(); err != nil {
-			logging.Errorf("Server error: %v", err)
-		}
-	}()
-
 	logging.Info("All services started successfully")
 
-	// Wait for interrupt signal
+	// SIGHUP triggers an encryption key rotation without a restart; SIGINT
+	// and SIGTERM trigger a graceful shutdown.
 	stop := make(chan os.Signal, 1)
 	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
-	<-stop
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+
+	for {
+		select {
+		case <-hup:
+			if err := rotateEncryptionKeys(backgroundCtx, authDataStore, capsuleStore); err != nil {
+				logging.WithError(err).Error("Failed to rotate encryption keys")
+			}
+			continue
+		case <-stop:
+		}
+		break
+	}
 
 	// Shutdown gracefully
 	logging.Info("Shutting down gracefully...")
 	shutdownCtx, cancel := sys_context.WithTimeout(backgroundCtx, 30*time.Second)
 	defer cancel()
 
-	// Stop event service
-	logging.Info("Stopping event service...")
-	if err := events.GetGlobalService().Stop(); err != nil {
-		logging.Errorf("Error stopping event service: %v", err)
-	} else {
-		logging.Info("Event service stopped successfully")
-	}
-
-	// Stop API server
-	logging.Info("Stopping API server...")
-	if err := server.Stop(shutdownCtx); err != nil {
-		logging.Errorf("Error shutting down server: %v", err)
-		return fmt.Errorf("error shutting down server: %w", err)
-	}
+	manager.Shutdown(shutdownCtx)
 
 	logging.Info("Application shutdown completed successfully")
 	return nil
 }
-
-func showUsage() {
-	fmt.Printf("%s - A command line tool for container management\n\n", AppName)
-	fmt.Println("Usage:")
-	fmt.Printf("  %s [options]\n\n", AppName)
-	fmt.Println("Options:")
-	fmt.Println("  --help              Show this help message")
-	fmt.Println("  --version           Show version information")
-	fmt.Println("  --config <path>     Path to configuration file (JSON or YAML)")
-	fmt.Println("  --port <port>       Port to run the API server on")
-	fmt.Println("  --hostname <host>   Hostname to run the API server on")
-	fmt.Println()
-	fmt.Println("Environment variables:")
-	fmt.Println()
-	fmt.Println("Configuration file formats supported: JSON, YAML")
-	fmt.Println()
-	fmt.Println("Examples:")
-	fmt.Printf("  %s --version\n", AppName)
-	fmt.Printf("  %s --config config.yaml\n", AppName)
-	fmt.Printf("  %s --username admin --password secret\n", AppName)
-}